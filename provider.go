@@ -2,8 +2,12 @@ package rfc2136
 
 import (
 	"context"
+	"crypto"
+	"crypto/tls"
 	"fmt"
+	"io"
 	"net"
+	"os"
 	"strings"
 	"sync"
 	"time"
@@ -27,162 +31,419 @@ type Provider struct {
 	// The secret used to compute the signature.
 	TSIGSecret string `json:"tsig_secret,omitempty"`
 
+	// Prerequisites are RFC 2136 UPDATE prerequisites checked by the server
+	// before any of AppendRecords', DeleteRecords', or SetRecords' changes
+	// are applied, e.g. to only create a record if it doesn't already
+	// exist. The whole UPDATE is rejected if any prerequisite isn't met.
+	Prerequisites []Prerequisite `json:"-"`
+
+	// Transport selects the network used to talk to Nameserver: "udp"
+	// (the default), "tcp", or "tcp-tls" for DNS-over-TLS. Zone transfers
+	// (GetRecords) always run over TCP, over TLS when this is "tcp-tls".
+	// When this is "udp", a truncated reply to an UPDATE is automatically
+	// retried over TCP, the same way stub resolvers handle it.
+	Transport string `json:"transport,omitempty"`
+
+	// TLSConfig configures the connection when Transport is "tcp-tls".
+	TLSConfig *tls.Config `json:"-"`
+
+	// SIG0Name is the owner name of the SIG(0) key used to sign UPDATE
+	// and zone transfer messages, i.e. the name of the KEY record
+	// published for it. Set this and SIG0KeyFile to authenticate with a
+	// SIG(0) keypair instead of TSIG.
+	SIG0Name string `json:"sig0_name,omitempty"`
+
+	// SIG0KeyFile is the path to the SIG(0) keypair for SIG0Name, in the
+	// format BIND's dnssec-keygen produces: a ".key" file holding the
+	// public KEY record, which is always read from disk, and a
+	// ".private" file of the same name alongside it holding the private
+	// key. Set SIG0Key to supply the private key's contents inline
+	// instead of reading the ".private" file, e.g. when it comes from a
+	// secrets manager rather than disk.
+	SIG0KeyFile string `json:"sig0_keyfile,omitempty"`
+
+	// SIG0Key is the private key file's contents inline, as an
+	// alternative to reading the ".private" file named by SIG0KeyFile.
+	SIG0Key string `json:"-"`
+
 	mutex sync.Mutex
+
+	zoneCacheMutex sync.Mutex
+	zoneCache      map[string]zoneCacheEntry
+}
+
+// zoneCacheTTL is how long FindZone caches a successful lookup before
+// walking the label hierarchy again.
+const zoneCacheTTL = 5 * time.Minute
+
+type zoneCacheEntry struct {
+	zone    string
+	expires time.Time
 }
 
-// GetRecords lists all the records in the zone.
+// FindZone walks up fqdn's label hierarchy, querying SOA at each level
+// against p.Nameserver, until it finds the zone authoritative for it. This
+// mirrors lego's acme.FindZoneByFqdn and lets a caller (e.g. an ACME DNS-01
+// solver) hand this provider a challenge name like
+// "_acme-challenge.foo.bar.example.com." without also knowing where the
+// zone cut is. Successful lookups are cached briefly per fqdn, so repeated
+// calls for the same name don't repeat the walk; a different name under the
+// same zone is walked again.
+func (p *Provider) FindZone(ctx context.Context, fqdn string) (string, error) {
+	fqdn = dns.Fqdn(fqdn)
+
+	p.zoneCacheMutex.Lock()
+	entry, ok := p.zoneCache[fqdn]
+	p.zoneCacheMutex.Unlock()
+	if ok && time.Now().Before(entry.expires) {
+		return entry.zone, nil
+	}
+
+	c := p.makeClient()
+	nameserver := p.normalizedNameserver()
+	labels := dns.SplitDomainName(fqdn)
+	for i := range labels {
+		name := dns.Fqdn(strings.Join(labels[i:], "."))
+
+		msg := new(dns.Msg)
+		msg.SetQuestion(name, dns.TypeSOA)
+		in, _, err := c.Exchange(msg, nameserver)
+		if err != nil {
+			return "", fmt.Errorf("failed to query SOA for %s: %w", name, err)
+		}
+
+		zone, ok := zoneFromSOAReply(in)
+		if !ok {
+			continue
+		}
+
+		p.zoneCacheMutex.Lock()
+		if p.zoneCache == nil {
+			p.zoneCache = make(map[string]zoneCacheEntry)
+		}
+		p.zoneCache[fqdn] = zoneCacheEntry{zone: zone, expires: time.Now().Add(zoneCacheTTL)}
+		p.zoneCacheMutex.Unlock()
+
+		return zone, nil
+	}
+
+	return "", fmt.Errorf("no SOA record found walking up from %s", fqdn)
+}
+
+// zoneFromSOAReply looks for a SOA record in a SOA query reply's answer
+// section (an authoritative hit) or authority section (a delegation), and
+// returns its owner name, which is the zone it's for.
+func zoneFromSOAReply(in *dns.Msg) (string, bool) {
+	for _, rr := range in.Answer {
+		if soa, ok := rr.(*dns.SOA); ok {
+			return soa.Hdr.Name, true
+		}
+	}
+	for _, rr := range in.Ns {
+		if soa, ok := rr.(*dns.SOA); ok {
+			return soa.Hdr.Name, true
+		}
+	}
+	return "", false
+}
+
+// Prerequisite is an RFC 2136 UPDATE prerequisite: a condition the server
+// checks before applying an UPDATE's changes.
+type Prerequisite struct {
+	// Name is the domain name the prerequisite applies to, relative to the
+	// zone (or "@"/"" for the zone apex).
+	Name string
+
+	// Type restricts the prerequisite to one RR type, e.g. "TXT". Leave
+	// empty to check for the existence of any RRset at Name regardless of
+	// type.
+	Type string
+
+	// Exists selects between a "must already exist" prerequisite (true)
+	// and a "must not exist" prerequisite (false).
+	Exists bool
+}
+
+// applyPrerequisites adds msg's prerequisite section from prereqs, resolving
+// each Name against zone.
+func applyPrerequisites(msg *dns.Msg, zone string, prereqs []Prerequisite) error {
+	for _, pr := range prereqs {
+		header := dns.RR_Header{Name: absoluteName(pr.Name, zone)}
+		if pr.Type != "" {
+			rrtype, ok := dns.StringToType[pr.Type]
+			if !ok {
+				return fmt.Errorf("unknown prerequisite type %q", pr.Type)
+			}
+			header.Rrtype = rrtype
+		}
+		rr := []dns.RR{&dns.ANY{Hdr: header}}
+
+		switch {
+		case pr.Type == "" && pr.Exists:
+			msg.NameUsed(rr)
+		case pr.Type == "" && !pr.Exists:
+			msg.NameNotUsed(rr)
+		case pr.Type != "" && pr.Exists:
+			msg.RRsetUsed(rr)
+		default:
+			msg.RRsetNotUsed(rr)
+		}
+	}
+
+	return nil
+}
+
+// GetRecords lists all the records in the zone, fetched via an AXFR zone
+// transfer. A single ANY query, which is what this used to do, is not
+// reliable: modern BIND/Knot/PowerDNS servers return little or nothing for
+// it, whereas AXFR is the standard, complete way to enumerate a zone.
 func (p *Provider) GetRecords(ctx context.Context, zone string) ([]libdns.Record, error) {
 	p.mutex.Lock()
 	defer p.mutex.Unlock()
 
-	// Do a DNS query for everything in the zone (ANY)
 	msg := new(dns.Msg)
-	msg.Id = dns.Id()
-	msg.RecursionDesired = true
-	msg.Question = make([]dns.Question, 1)
-	msg.Question[0] = dns.Question{Name: zone, Qtype: dns.TypeANY, Qclass: dns.ClassINET}
-	in, err := dns.Exchange(msg, p.normalizedNameserver())
-	if err != nil {
+	msg.SetAxfr(zone)
+	if err := p.configureMessage(msg); err != nil {
 		return nil, err
 	}
 
-	// Collect the records
+	return p.transfer(msg)
+}
+
+// transfer runs msg (already built by SetAxfr) as a zone transfer over TCP
+// (over TLS when Transport is "tcp-tls") and collects every RR from every
+// envelope into libdns.Records.
+//
+// IXFR-based incremental transfers are not offered: an IXFR reply is a
+// sequence of delete/add deltas, not a flat list of current records, and
+// flattening it the way this does would report deleted records as if they
+// were still current.
+func (p *Provider) transfer(msg *dns.Msg) ([]libdns.Record, error) {
+	tr := new(dns.Transfer)
+	if len(p.TSIGKeyName) > 0 && len(p.TSIGSecret) > 0 {
+		tr.TsigSecret = map[string]string{dns.Fqdn(p.TSIGKeyName): p.TSIGSecret}
+	}
+
+	nameserver := p.normalizedNameserver()
+	if p.transport() == "tcp-tls" {
+		conn, err := p.makeClient().Dial(nameserver)
+		if err != nil {
+			return nil, fmt.Errorf("failed to start zone transfer: %w", err)
+		}
+		tr.Conn = conn
+	}
+
+	envelopes, err := tr.In(msg, nameserver)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start zone transfer: %w", err)
+	}
+
 	fetchedRecords := []libdns.Record{}
-	for _, record := range in.Answer {
-		header := record.Header()
-		fetchedRecords = append(fetchedRecords, libdns.Record{
-			Name:  header.Name,
-			Type:  dns.Type(header.Rrtype).String(),
-			Value: record.String(),
-			TTL:   time.Duration(header.Ttl),
-		})
+	for ex := range envelopes {
+		if ex.Error != nil {
+			return nil, fmt.Errorf("zone transfer failed: %w", ex.Error)
+		}
+		for _, rr := range ex.RR {
+			header := rr.Header()
+			if header.Rrtype == dns.TypeSOA && len(fetchedRecords) > 0 {
+				// AXFR/IXFR end with a repeat of the starting SOA; no
+				// need to report the zone apex twice.
+				continue
+			}
+			fetchedRecords = append(fetchedRecords, libdns.Record{
+				Name:  header.Name,
+				Type:  dns.Type(header.Rrtype).String(),
+				Value: rdataString(rr),
+				TTL:   time.Duration(header.Ttl) * time.Second,
+			})
+		}
 	}
 
 	return fetchedRecords, nil
 }
 
-// AppendRecords adds records to the zone and returns the records that were created.
+// rdataString returns just the RDATA portion of rr, i.e. rr.String()
+// without the leading "name ttl class type" header, so it round-trips
+// through rrFromRecord as a libdns.Record's Value.
+func rdataString(rr dns.RR) string {
+	if txt, ok := rr.(*dns.TXT); ok {
+		// rr.String() would return the zone-file-quoted form (e.g.
+		// `"foo" "bar"`), but rrFromRecord stores TXT values verbatim in
+		// dns.TXT.Txt rather than quoting them; joining the raw strings
+		// here keeps both directions of the round trip in agreement.
+		return strings.Join(txt.Txt, "")
+	}
+	return strings.TrimPrefix(rr.String(), rr.Header().String())
+}
+
+// AppendRecords adds records to the zone and returns the records that were
+// created. All records are packed into a single UPDATE message, so the
+// server applies them atomically: either every record is added, or (on
+// error) none are.
 func (p *Provider) AppendRecords(ctx context.Context, zone string, records []libdns.Record) ([]libdns.Record, error) {
 	p.mutex.Lock()
 	defer p.mutex.Unlock()
 
-	c := p.makeClient()
-	nameserver := p.normalizedNameserver()
+	msg := new(dns.Msg)
+	msg.SetUpdate(zone)
+	if err := applyPrerequisites(msg, zone, p.Prerequisites); err != nil {
+		return nil, fmt.Errorf("failed to append records: %w", err)
+	}
 
-	var appendedRecords []libdns.Record
 	for _, record := range records {
 		rr, err := rrFromRecord(zone, record)
 		if err != nil {
-			return appendedRecords, fmt.Errorf("failed to append record: %w", err)
-		}
-
-		rrs := []dns.RR{rr}
-
-		msg := new(dns.Msg)
-		msg.SetUpdate(zone)
-		// TODO: We may also need to `msg.RemoveRRset(rrs)` here to clean up just in case
-		msg.Insert(rrs)
-		p.configureMessage(msg)
-
-		reply, _, err := c.Exchange(msg, nameserver)
-		if err != nil {
-			return appendedRecords, fmt.Errorf("failed to append record, %w", err)
-		}
-		if reply != nil && reply.Rcode != dns.RcodeSuccess {
-			return appendedRecords, fmt.Errorf("failed to append record, server replied %s", dns.RcodeToString[reply.Rcode])
+			return nil, fmt.Errorf("failed to append records: %w", err)
 		}
+		msg.Insert([]dns.RR{rr})
+	}
 
-		appendedRecords = append(appendedRecords, record)
+	if err := p.sendUpdate(msg); err != nil {
+		return nil, fmt.Errorf("failed to append records: %w", err)
 	}
 
-	return appendedRecords, nil
+	return records, nil
 }
 
-// DeleteRecords deletes records from the zone and returns the records that were deleted.
+// DeleteRecords deletes records from the zone and returns the records that
+// were deleted. All records are packed into a single UPDATE message, so the
+// server applies them atomically: either every record is removed, or (on
+// error) none are.
 func (p *Provider) DeleteRecords(ctx context.Context, zone string, records []libdns.Record) ([]libdns.Record, error) {
 	p.mutex.Lock()
 	defer p.mutex.Unlock()
 
-	c := p.makeClient()
-	nameserver := p.normalizedNameserver()
+	msg := new(dns.Msg)
+	msg.SetUpdate(zone)
+	if err := applyPrerequisites(msg, zone, p.Prerequisites); err != nil {
+		return nil, fmt.Errorf("failed to delete records: %w", err)
+	}
 
-	var deletedRecords []libdns.Record
 	for _, record := range records {
 		rr, err := rrFromRecord(zone, record)
 		if err != nil {
-			return deletedRecords, fmt.Errorf("failed to append record: %w", err)
-		}
-
-		rrs := []dns.RR{rr}
-
-		msg := new(dns.Msg)
-		msg.SetUpdate(zone)
-		msg.Remove(rrs)
-		p.configureMessage(msg)
-
-		reply, _, err := c.Exchange(msg, nameserver)
-		if err != nil {
-			return deletedRecords, fmt.Errorf("failed to append record, %w", err)
-		}
-		if reply != nil && reply.Rcode != dns.RcodeSuccess {
-			return deletedRecords, fmt.Errorf("failed to append record, server replied %s", dns.RcodeToString[reply.Rcode])
+			return nil, fmt.Errorf("failed to delete records: %w", err)
 		}
+		msg.Remove([]dns.RR{rr})
+	}
 
-		deletedRecords = append(deletedRecords, record)
+	if err := p.sendUpdate(msg); err != nil {
+		return nil, fmt.Errorf("failed to delete records: %w", err)
 	}
 
-	return deletedRecords, nil
+	return records, nil
 }
 
-// SetRecords sets the records in the zone, either by updating existing records or creating new ones, and returns the records that were updated.
+// SetRecords sets the records in the zone, either by updating existing
+// records or creating new ones, and returns the records that were updated.
+// All records are packed into a single UPDATE message, so the server
+// applies them atomically: either every record is set, or (on error) none
+// are.
 func (p *Provider) SetRecords(ctx context.Context, zone string, records []libdns.Record) ([]libdns.Record, error) {
 	p.mutex.Lock()
 	defer p.mutex.Unlock()
 
-	c := p.makeClient()
-	nameserver := p.normalizedNameserver()
+	msg := new(dns.Msg)
+	msg.SetUpdate(zone)
+	if err := applyPrerequisites(msg, zone, p.Prerequisites); err != nil {
+		return nil, fmt.Errorf("failed to set records: %w", err)
+	}
 
-	var setRecords []libdns.Record
-	for _, record := range records {
+	rrs := make([]dns.RR, len(records))
+	for i, record := range records {
 		rr, err := rrFromRecord(zone, record)
 		if err != nil {
-			return setRecords, fmt.Errorf("failed to append record: %w", err)
+			return nil, fmt.Errorf("failed to set records: %w", err)
 		}
+		rrs[i] = rr
+	}
 
-		rrs := []dns.RR{rr}
+	// Remove each distinct (name, type) RRset exactly once, before
+	// inserting any of the new records. Interleaving RemoveRRset/Insert
+	// per record breaks when two records share an RRset (e.g. two TXT
+	// values): the server applies the update section in order, so a
+	// later RemoveRRset would wipe out an Insert that came earlier in
+	// the same message.
+	type rrset struct {
+		name   string
+		rrtype uint16
+	}
+	removed := make(map[rrset]bool, len(rrs))
+	for _, rr := range rrs {
+		key := rrset{rr.Header().Name, rr.Header().Rrtype}
+		if removed[key] {
+			continue
+		}
+		removed[key] = true
+		msg.RemoveRRset([]dns.RR{rr})
+	}
+	msg.Insert(rrs)
 
-		msg := new(dns.Msg)
-		msg.SetUpdate(zone)
-		msg.RemoveRRset(rrs)
-		msg.Insert(rrs)
-		p.configureMessage(msg)
+	if err := p.sendUpdate(msg); err != nil {
+		return nil, fmt.Errorf("failed to set records: %w", err)
+	}
 
-		reply, _, err := c.Exchange(msg, nameserver)
-		if err != nil {
-			return setRecords, fmt.Errorf("failed to append record, %w", err)
-		}
-		if reply != nil && reply.Rcode != dns.RcodeSuccess {
-			return setRecords, fmt.Errorf("failed to append record, server replied %s", dns.RcodeToString[reply.Rcode])
-		}
+	return records, nil
+}
 
-		setRecords = append(setRecords, record)
+// sendUpdate signs msg (with SIG(0) or TSIG, whichever is configured) and
+// sends it to the nameserver, returning an error if the transport fails or
+// the server rejects the update. UPDATE messages regularly exceed 512
+// bytes (multiple TXT records, DNSSEC-signed zones, batched changes); if
+// the server truncates a UDP reply, the message is automatically retried
+// over TCP.
+func (p *Provider) sendUpdate(msg *dns.Msg) error {
+	if err := p.configureMessage(msg); err != nil {
+		return err
 	}
 
-	return setRecords, nil
+	c := p.makeClient()
+	nameserver := p.normalizedNameserver()
+
+	reply, _, err := c.Exchange(msg, nameserver)
+	if err == nil && reply != nil && reply.Truncated && c.Net == "udp" {
+		tcpClient := *c
+		tcpClient.Net = "tcp"
+		reply, _, err = tcpClient.Exchange(msg, nameserver)
+	}
+	if err != nil {
+		return err
+	}
+	if reply != nil && reply.Rcode != dns.RcodeSuccess {
+		return fmt.Errorf("server replied %s", dns.RcodeToString[reply.Rcode])
+	}
+
+	return nil
 }
 
 // Make a DNS client from the provider config
 func (p *Provider) makeClient() *dns.Client {
 	c := new(dns.Client)
 	c.SingleInflight = true
+	c.Net = p.transport()
+	if c.Net == "tcp-tls" {
+		c.TLSConfig = p.TLSConfig
+	}
 	if len(p.TSIGKeyName) > 0 && len(p.TSIGSecret) > 0 {
 		c.TsigSecret = map[string]string{dns.Fqdn(p.TSIGKeyName): p.TSIGSecret}
 	}
 	return c
 }
 
-// Configure the DNS message with TSIG if configured
-func (p *Provider) configureMessage(msg *dns.Msg) {
+// transport returns the configured Transport, defaulting to "udp".
+func (p *Provider) transport() string {
+	if p.Transport == "" {
+		return "udp"
+	}
+	return p.Transport
+}
+
+// configureMessage authenticates msg in place, with SIG(0) if SIG0KeyFile
+// is set, otherwise with TSIG if configured. It's a no-op if neither is.
+func (p *Provider) configureMessage(msg *dns.Msg) error {
+	if p.SIG0KeyFile != "" {
+		return p.signSIG0(msg)
+	}
 	if len(p.TSIGKeyName) > 0 && len(p.TSIGSecret) > 0 {
 		msg.SetTsig(
 			dns.Fqdn(p.TSIGKeyName),
@@ -191,57 +452,206 @@ func (p *Provider) configureMessage(msg *dns.Msg) {
 			time.Now().Unix(),
 		)
 	}
+	return nil
+}
+
+// signSIG0 signs msg with the keypair configured via SIG0Name/SIG0KeyFile,
+// appending the resulting SIG RR to msg.Extra.
+func (p *Provider) signSIG0(msg *dns.Msg) error {
+	key, priv, err := p.loadSIG0Key()
+	if err != nil {
+		return fmt.Errorf("failed to load SIG(0) key: %w", err)
+	}
+
+	signerName := key.Hdr.Name
+	if p.SIG0Name != "" {
+		signerName = dns.Fqdn(p.SIG0Name)
+	}
+
+	sig := new(dns.SIG)
+	sig.Hdr = dns.RR_Header{Name: signerName, Rrtype: dns.TypeSIG, Class: dns.ClassANY}
+	sig.Algorithm = key.Algorithm
+	sig.SignerName = signerName
+	sig.KeyTag = key.KeyTag()
+	sig.Inception = uint32(time.Now().Add(-5 * time.Minute).Unix())
+	sig.Expiration = uint32(time.Now().Add(5 * time.Minute).Unix())
+
+	signer, ok := priv.(crypto.Signer)
+	if !ok {
+		return fmt.Errorf("SIG0KeyFile %s does not contain a signing key", p.SIG0KeyFile)
+	}
+	if _, err := sig.Sign(signer, msg); err != nil {
+		return fmt.Errorf("failed to sign message: %w", err)
+	}
+	msg.Extra = append(msg.Extra, sig)
+
+	return nil
 }
 
-// Convert a zone + libdns record into a dns.RR
+// loadSIG0Key reads the SIG(0) keypair configured via SIG0KeyFile/SIG0Key:
+// the public KEY record from the ".key" file alongside SIG0KeyFile, and the
+// private key either from SIG0Key inline, if set, or from SIG0KeyFile
+// itself.
+func (p *Provider) loadSIG0Key() (*dns.KEY, crypto.PrivateKey, error) {
+	pubFilename := strings.TrimSuffix(p.SIG0KeyFile, ".private") + ".key"
+
+	pubFile, err := os.Open(pubFilename)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer pubFile.Close()
+
+	rr, err := dns.ReadRR(pubFile, pubFilename)
+	if err != nil {
+		return nil, nil, err
+	}
+	key, ok := rr.(*dns.KEY)
+	if !ok {
+		return nil, nil, fmt.Errorf("%s does not contain a KEY record", pubFilename)
+	}
+
+	var (
+		privSource io.Reader
+		privName   = p.SIG0KeyFile
+	)
+	if p.SIG0Key != "" {
+		privSource = strings.NewReader(p.SIG0Key)
+		privName = "SIG0Key"
+	} else {
+		privFile, err := os.Open(p.SIG0KeyFile)
+		if err != nil {
+			return nil, nil, err
+		}
+		defer privFile.Close()
+		privSource = privFile
+	}
+
+	priv, err := key.ReadPrivateKey(privSource, privName)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return key, priv, nil
+}
+
+// Convert a zone + libdns record into a dns.RR.
+//
+// Rather than hand-coding a case per RR type (which libdns.Record's
+// Name/Type/Value/TTL fields don't map onto uniformly), we assemble the
+// equivalent zone-file line and let dns.NewRR parse it, since miekg/dns
+// already knows how to read RDATA for every type it supports (SRV, CAA,
+// TLSA, NS, PTR, SOA, DS, DNSKEY, SSHFP, NAPTR, HTTPS, SVCB, and more).
+//
+// For RR types whose RDATA has more than one field (MX, SRV, CAA, ...),
+// Value must hold the full space-separated RDATA, e.g. "10 mail.example.com."
+// for MX or "10 20 5269 sip.example.com." for SRV. MXRecord and SRVRecord
+// below build a correctly formatted libdns.Record for the common cases so
+// callers don't have to assemble that string by hand.
 func rrFromRecord(zone string, record libdns.Record) (dns.RR, error) {
-	header := dns.RR_Header{
-		Name:   zone,
-		Rrtype: dns.StringToType[record.Type],
-		Class:  dns.ClassINET,
-		Ttl:    uint32(record.TTL),
+	name := absoluteName(record.Name, zone)
+	ttl := uint32(record.TTL / time.Second)
+
+	if record.Type == "TXT" {
+		// TXT values routinely contain spaces and ';' (DKIM/DMARC/SPF
+		// records, e.g. "v=DKIM1; k=rsa; p=..."), both of which are
+		// meaningful in zone-file syntax (word separator and start of a
+		// comment, respectively). Build the RR directly instead of
+		// going through dns.NewRR so Value is taken verbatim.
+		return &dns.TXT{
+			Hdr: dns.RR_Header{Name: name, Rrtype: dns.TypeTXT, Class: dns.ClassINET, Ttl: ttl},
+			Txt: splitTXT(record.Value),
+		}, nil
 	}
 
-	var rr dns.RR
+	value := record.Value
 	switch record.Type {
-	case "A":
-		rr := new(dns.A)
-		rr.Hdr = header
-		rr.A = net.IP(record.Value)
-	case "AAAA":
-		rr := new(dns.AAAA)
-		rr.Hdr = header
-		rr.AAAA = net.IP(record.Value)
-	case "CNAME":
-		rr := new(dns.CNAME)
-		rr.Hdr = header
-		rr.Target = record.Value
-	case "MX":
-		rr := new(dns.MX)
-		rr.Hdr = header
-		rr.Mx = record.Value
-		// TODO: How to we grab rr.Preference from libdns.Record?
-	case "TXT":
-		rr := new(dns.TXT)
-		rr.Hdr = header
-		rr.Txt = []string{record.Value}
-	default:
-		// Unsupported type, so we do nothing.
-		// I couldn't find a simple way to support all the record types
-		// dynamically, because each record has different fields to fill
-		// to satisfy github.com/miekg/dns. Maybe we could use dns.NewRR()
-		// if we can figure out a reliable way to construct the string.
-		return nil, fmt.Errorf("unsupported type %s", record.Type)
+	case "A", "AAAA":
+		// dns.NewRR parses the textual form of an IP address, not the
+		// raw bytes net.IP.String() would need; make sure Value is
+		// actually one before handing it off.
+		if net.ParseIP(value) == nil {
+			return nil, fmt.Errorf("invalid IP address %q for %s record", value, record.Type)
+		}
+	}
+
+	line := fmt.Sprintf("%s %d IN %s %s", name, ttl, record.Type, value)
+	rr, err := dns.NewRR(line)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse record %q: %w", line, err)
+	}
+	if rr == nil {
+		return nil, fmt.Errorf("unsupported or empty record type %q", record.Type)
 	}
 
 	return rr, nil
 }
 
-// Append the default DNS port if none is specified.
+// txtChunkSize is the maximum length of a single DNS character-string, the
+// unit TXT RDATA is built from (RFC 1035 section 3.3).
+const txtChunkSize = 255
+
+// splitTXT splits s into txtChunkSize-byte character-strings, since a TXT
+// record can't hold a value longer than that in a single one, e.g. a DKIM
+// public key. rdataString joins them back into one string on read.
+func splitTXT(s string) []string {
+	if len(s) <= txtChunkSize {
+		return []string{s}
+	}
+
+	chunks := make([]string, 0, (len(s)+txtChunkSize-1)/txtChunkSize)
+	for len(s) > txtChunkSize {
+		chunks = append(chunks, s[:txtChunkSize])
+		s = s[txtChunkSize:]
+	}
+	return append(chunks, s)
+}
+
+// absoluteName resolves a record name that may be relative to zone (or "@"
+// for the zone apex) into a fully-qualified name suitable for a zone-file
+// line.
+func absoluteName(name, zone string) string {
+	if name == "" || name == "@" {
+		return zone
+	}
+	if dns.IsFqdn(name) {
+		return name
+	}
+	return name + "." + zone
+}
+
+// MXRecord builds a libdns.Record for an MX record. libdns.Record's single
+// Value field can't carry both the preference and the mail exchange host,
+// so this formats the "preference exchange" pair dns.NewRR expects.
+func MXRecord(name string, ttl time.Duration, preference uint16, exchange string) libdns.Record {
+	return libdns.Record{
+		Type:  "MX",
+		Name:  name,
+		Value: fmt.Sprintf("%d %s", preference, dns.Fqdn(exchange)),
+		TTL:   ttl,
+	}
+}
+
+// SRVRecord builds a libdns.Record for an SRV record, for the same reason as
+// MXRecord: priority, weight, and port don't fit into Value on their own.
+func SRVRecord(name string, ttl time.Duration, priority, weight, port uint16, target string) libdns.Record {
+	return libdns.Record{
+		Type:  "SRV",
+		Name:  name,
+		Value: fmt.Sprintf("%d %d %d %s", priority, weight, port, dns.Fqdn(target)),
+		TTL:   ttl,
+	}
+}
+
+// Append the default DNS port if none is specified: 853 for DNS-over-TLS
+// (Transport "tcp-tls"), 53 otherwise.
 func (p *Provider) normalizedNameserver() string {
 	if _, _, err := net.SplitHostPort(p.Nameserver); err != nil {
 		if strings.Contains(err.Error(), "missing port") {
-			return net.JoinHostPort(p.Nameserver, "53")
+			port := "53"
+			if p.transport() == "tcp-tls" {
+				port = "853"
+			}
+			return net.JoinHostPort(p.Nameserver, port)
 		}
 	}
 	return p.Nameserver
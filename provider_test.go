@@ -0,0 +1,139 @@
+package rfc2136
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/libdns/libdns"
+	"github.com/miekg/dns"
+)
+
+func TestAbsoluteName(t *testing.T) {
+	tests := []struct {
+		name string
+		zone string
+		want string
+	}{
+		{name: "", zone: "example.com.", want: "example.com."},
+		{name: "@", zone: "example.com.", want: "example.com."},
+		{name: "www", zone: "example.com.", want: "www.example.com."},
+		{name: "www.example.com.", zone: "example.com.", want: "www.example.com."},
+	}
+
+	for _, tt := range tests {
+		if got := absoluteName(tt.name, tt.zone); got != tt.want {
+			t.Errorf("absoluteName(%q, %q) = %q, want %q", tt.name, tt.zone, got, tt.want)
+		}
+	}
+}
+
+func TestRRFromRecord(t *testing.T) {
+	const zone = "example.com."
+
+	t.Run("A", func(t *testing.T) {
+		rr, err := rrFromRecord(zone, libdns.Record{Type: "A", Name: "www", Value: "192.0.2.1", TTL: 300 * time.Second})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		a, ok := rr.(*dns.A)
+		if !ok {
+			t.Fatalf("got %T, want *dns.A", rr)
+		}
+		if a.A.String() != "192.0.2.1" {
+			t.Errorf("A = %s, want 192.0.2.1", a.A)
+		}
+		if a.Hdr.Ttl != 300 {
+			t.Errorf("Ttl = %d, want 300", a.Hdr.Ttl)
+		}
+	})
+
+	t.Run("invalid A", func(t *testing.T) {
+		if _, err := rrFromRecord(zone, libdns.Record{Type: "A", Name: "www", Value: "not-an-ip"}); err == nil {
+			t.Fatal("expected an error for an invalid IP, got nil")
+		}
+	})
+
+	t.Run("MX", func(t *testing.T) {
+		record := MXRecord("@", 3600*time.Second, 10, "mail.example.com.")
+		rr, err := rrFromRecord(zone, record)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		mx, ok := rr.(*dns.MX)
+		if !ok {
+			t.Fatalf("got %T, want *dns.MX", rr)
+		}
+		if mx.Preference != 10 || mx.Mx != "mail.example.com." {
+			t.Errorf("got preference=%d mx=%q, want preference=10 mx=%q", mx.Preference, mx.Mx, "mail.example.com.")
+		}
+	})
+
+	t.Run("SRV", func(t *testing.T) {
+		record := SRVRecord("_sip._tcp", 3600*time.Second, 10, 20, 5269, "sip.example.com.")
+		rr, err := rrFromRecord(zone, record)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		srv, ok := rr.(*dns.SRV)
+		if !ok {
+			t.Fatalf("got %T, want *dns.SRV", rr)
+		}
+		if srv.Priority != 10 || srv.Weight != 20 || srv.Port != 5269 || srv.Target != "sip.example.com." {
+			t.Errorf("got %+v, unexpected fields", srv)
+		}
+	})
+
+	t.Run("TXT is stored verbatim", func(t *testing.T) {
+		value := `v=DKIM1; k=rsa; p=not a real key, just a value with spaces`
+		rr, err := rrFromRecord(zone, libdns.Record{Type: "TXT", Name: "@", Value: value, TTL: 300 * time.Second})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		txt, ok := rr.(*dns.TXT)
+		if !ok {
+			t.Fatalf("got %T, want *dns.TXT", rr)
+		}
+		if got := strings.Join(txt.Txt, ""); got != value {
+			t.Errorf("got %q, want %q", got, value)
+		}
+	})
+
+	t.Run("long TXT is split into 255-byte character-strings", func(t *testing.T) {
+		value := strings.Repeat("a", 600)
+		rr, err := rrFromRecord(zone, libdns.Record{Type: "TXT", Name: "@", Value: value})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		txt := rr.(*dns.TXT)
+		for i, chunk := range txt.Txt {
+			if len(chunk) > txtChunkSize {
+				t.Errorf("chunk %d has length %d, want <= %d", i, len(chunk), txtChunkSize)
+			}
+		}
+		if got := strings.Join(txt.Txt, ""); got != value {
+			t.Errorf("joined chunks = %q (len %d), want original value (len %d)", got, len(got), len(value))
+		}
+	})
+}
+
+// TestTXTRoundTrip checks that a TXT value written by rrFromRecord reads
+// back unchanged through rdataString, even when it contains zone-file
+// metacharacters or is long enough to need multiple character-strings.
+func TestTXTRoundTrip(t *testing.T) {
+	values := []string{
+		"plain",
+		"v=DKIM1; k=rsa; p=something with spaces; and more",
+		strings.Repeat("k", 600),
+	}
+
+	for _, value := range values {
+		rr, err := rrFromRecord("example.com.", libdns.Record{Type: "TXT", Name: "@", Value: value})
+		if err != nil {
+			t.Fatalf("rrFromRecord: unexpected error: %v", err)
+		}
+		if got := rdataString(rr); got != value {
+			t.Errorf("rdataString round trip = %q, want %q", got, value)
+		}
+	}
+}